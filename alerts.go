@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AlertSubscription represents a chat's request to be notified while a venue's
+// usage satisfies a threshold, e.g. "notify me when lazdynai usage <= 30%".
+type AlertSubscription struct {
+	ID          int
+	ChatID      string
+	Venue       string
+	Comparator  string
+	Threshold   int
+	Cooldown    time.Duration
+	LastFiredAt *time.Time
+}
+
+// initAlertsTable creates the alert_subscriptions table if it doesn't exist.
+func initAlertsTable(pool *pgxpool.Pool) error {
+	createTableQuery := `
+    CREATE TABLE IF NOT EXISTS alert_subscriptions (
+        id SERIAL PRIMARY KEY,
+        chat_id TEXT NOT NULL,
+        venue TEXT NOT NULL DEFAULT '` + defaultVenueID + `',
+        comparator TEXT NOT NULL,
+        threshold INT NOT NULL,
+        cooldown_seconds INT NOT NULL DEFAULT 1800,
+        last_fired_at TIMESTAMPTZ
+    );`
+
+	_, err := pool.Exec(context.Background(), createTableQuery)
+	if err != nil {
+		return fmt.Errorf("error creating alert_subscriptions table: %v", err)
+	}
+	return nil
+}
+
+// matchesThreshold reports whether usage satisfies the subscription's comparator/threshold.
+func (s AlertSubscription) matchesThreshold(usage int) bool {
+	switch s.Comparator {
+	case "<=":
+		return usage <= s.Threshold
+	case "<":
+		return usage < s.Threshold
+	case ">=":
+		return usage >= s.Threshold
+	case ">":
+		return usage > s.Threshold
+	case "=", "==":
+		return usage == s.Threshold
+	default:
+		return false
+	}
+}
+
+// dueForAlert reports whether the subscription is allowed to fire again,
+// i.e. its cooldown has elapsed since it last fired.
+func (s AlertSubscription) dueForAlert(now time.Time) bool {
+	if s.LastFiredAt == nil {
+		return true
+	}
+	return now.Sub(*s.LastFiredAt) >= s.Cooldown
+}
+
+// loadAlertSubscriptions fetches every subscription registered for venue.
+func loadAlertSubscriptions(pool *pgxpool.Pool, venue string) ([]AlertSubscription, error) {
+	rows, err := pool.Query(context.Background(),
+		"SELECT id, chat_id, venue, comparator, threshold, cooldown_seconds, last_fired_at FROM alert_subscriptions WHERE venue = $1",
+		venue)
+	if err != nil {
+		return nil, fmt.Errorf("error loading alert subscriptions: %v", err)
+	}
+	defer rows.Close()
+
+	var subs []AlertSubscription
+	for rows.Next() {
+		var s AlertSubscription
+		var cooldownSeconds int
+		var lastFiredAt *time.Time
+		if err := rows.Scan(&s.ID, &s.ChatID, &s.Venue, &s.Comparator, &s.Threshold, &cooldownSeconds, &lastFiredAt); err != nil {
+			return nil, fmt.Errorf("error scanning alert subscription: %v", err)
+		}
+		s.Cooldown = time.Duration(cooldownSeconds) * time.Second
+		s.LastFiredAt = lastFiredAt
+		subs = append(subs, s)
+	}
+	return subs, rows.Err()
+}
+
+// loadAlertSubscriptionsForChat fetches every subscription registered by chatID, across venues.
+func loadAlertSubscriptionsForChat(pool *pgxpool.Pool, chatID string) ([]AlertSubscription, error) {
+	rows, err := pool.Query(context.Background(),
+		"SELECT id, chat_id, venue, comparator, threshold, cooldown_seconds, last_fired_at FROM alert_subscriptions WHERE chat_id = $1",
+		chatID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading alert subscriptions: %v", err)
+	}
+	defer rows.Close()
+
+	var subs []AlertSubscription
+	for rows.Next() {
+		var s AlertSubscription
+		var cooldownSeconds int
+		var lastFiredAt *time.Time
+		if err := rows.Scan(&s.ID, &s.ChatID, &s.Venue, &s.Comparator, &s.Threshold, &cooldownSeconds, &lastFiredAt); err != nil {
+			return nil, fmt.Errorf("error scanning alert subscription: %v", err)
+		}
+		s.Cooldown = time.Duration(cooldownSeconds) * time.Second
+		s.LastFiredAt = lastFiredAt
+		subs = append(subs, s)
+	}
+	return subs, rows.Err()
+}
+
+// markAlertFired records that a subscription just notified its chat.
+func markAlertFired(pool *pgxpool.Pool, id int, firedAt time.Time) error {
+	_, err := pool.Exec(context.Background(),
+		"UPDATE alert_subscriptions SET last_fired_at = $1 WHERE id = $2", firedAt, id)
+	if err != nil {
+		return fmt.Errorf("error updating last_fired_at: %v", err)
+	}
+	return nil
+}
+
+// addAlertSubscription inserts a new subscription for chatID on venue.
+func addAlertSubscription(pool *pgxpool.Pool, chatID, venue, comparator string, threshold int, cooldown time.Duration) error {
+	_, err := pool.Exec(context.Background(),
+		"INSERT INTO alert_subscriptions (chat_id, venue, comparator, threshold, cooldown_seconds) VALUES ($1, $2, $3, $4, $5)",
+		chatID, venue, comparator, threshold, int(cooldown.Seconds()))
+	if err != nil {
+		return fmt.Errorf("error adding alert subscription: %v", err)
+	}
+	return nil
+}
+
+// removeAlertSubscription deletes subscription id, scoped to chatID so one chat
+// cannot remove another chat's subscription.
+func removeAlertSubscription(pool *pgxpool.Pool, chatID string, id int) error {
+	tag, err := pool.Exec(context.Background(),
+		"DELETE FROM alert_subscriptions WHERE id = $1 AND chat_id = $2", id, chatID)
+	if err != nil {
+		return fmt.Errorf("error removing alert subscription: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// evaluateAlerts checks every subscription for venue against the latest usage reading
+// and sends a Telegram message for each one that the reading satisfies and whose cooldown
+// has elapsed. It does not compare against the previous reading, so a subscription keeps
+// firing once per cooldown for as long as the threshold stays satisfied.
+func evaluateAlerts(pool *pgxpool.Pool, botToken, venue string, usage int) {
+	subs, err := loadAlertSubscriptions(pool, venue)
+	if err != nil {
+		log.Printf("Error loading alert subscriptions for venue %q: %v\n", venue, err)
+		return
+	}
+
+	now := time.Now()
+	for _, sub := range subs {
+		if !sub.matchesThreshold(usage) || !sub.dueForAlert(now) {
+			continue
+		}
+
+		message := fmt.Sprintf("%s usage is %d%% (subscription: %s %d%%)", venue, usage, sub.Comparator, sub.Threshold)
+		if err := sendTelegramMessage(botToken, sub.ChatID, message); err != nil {
+			log.Printf("Error sending alert to chat %s: %v\n", sub.ChatID, err)
+			continue
+		}
+
+		if err := markAlertFired(pool, sub.ID, now); err != nil {
+			log.Printf("Error marking alert %d as fired: %v\n", sub.ID, err)
+		}
+	}
+}
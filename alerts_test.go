@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAlertSubscriptionMatchesThreshold(t *testing.T) {
+	tests := []struct {
+		name       string
+		comparator string
+		threshold  int
+		usage      int
+		want       bool
+	}{
+		{"<= matches equal", "<=", 30, 30, true},
+		{"<= matches below", "<=", 30, 20, true},
+		{"<= rejects above", "<=", 30, 40, false},
+		{"< rejects equal", "<", 30, 30, false},
+		{">= matches equal", ">=", 80, 80, true},
+		{"> rejects equal", ">", 80, 80, false},
+		{"= matches equal", "=", 50, 50, true},
+		{"== matches equal", "==", 50, 50, true},
+		{"unknown comparator never matches", "!=", 50, 50, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := AlertSubscription{Comparator: tt.comparator, Threshold: tt.threshold}
+			if got := s.matchesThreshold(tt.usage); got != tt.want {
+				t.Errorf("matchesThreshold(%d) = %v, want %v", tt.usage, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertSubscriptionDueForAlert(t *testing.T) {
+	now := time.Date(2026, 7, 26, 12, 0, 0, 0, time.UTC)
+
+	t.Run("never fired is due", func(t *testing.T) {
+		s := AlertSubscription{Cooldown: 30 * time.Minute}
+		if !s.dueForAlert(now) {
+			t.Error("dueForAlert() = false, want true when LastFiredAt is nil")
+		}
+	})
+
+	t.Run("within cooldown is not due", func(t *testing.T) {
+		last := now.Add(-10 * time.Minute)
+		s := AlertSubscription{Cooldown: 30 * time.Minute, LastFiredAt: &last}
+		if s.dueForAlert(now) {
+			t.Error("dueForAlert() = true, want false within cooldown")
+		}
+	})
+
+	t.Run("cooldown elapsed is due", func(t *testing.T) {
+		last := now.Add(-31 * time.Minute)
+		s := AlertSubscription{Cooldown: 30 * time.Minute, LastFiredAt: &last}
+		if !s.dueForAlert(now) {
+			t.Error("dueForAlert() = false, want true once cooldown has elapsed")
+		}
+	})
+
+	t.Run("exactly at cooldown boundary is due", func(t *testing.T) {
+		last := now.Add(-30 * time.Minute)
+		s := AlertSubscription{Cooldown: 30 * time.Minute, LastFiredAt: &last}
+		if !s.dueForAlert(now) {
+			t.Error("dueForAlert() = false, want true at exact cooldown boundary")
+		}
+	})
+}
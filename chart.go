@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/wcharczuk/go-chart/v2"
+)
+
+// chartCacheTTL bounds how long a rendered chart is reused for repeated /stats calls.
+const chartCacheTTL = 5 * time.Minute
+
+// chartCacheKey identifies a renderable chart by venue, window and the bucket the
+// window's data currently falls into, so a new bucket starts a new cache entry.
+type chartCacheKey struct {
+	Venue       string
+	Window      string
+	BucketStart time.Time
+}
+
+type chartCacheEntry struct {
+	png        []byte
+	renderedAt time.Time
+}
+
+var (
+	chartCacheMu    sync.Mutex
+	chartCacheStore = map[chartCacheKey]chartCacheEntry{}
+)
+
+// renderCachedChart returns the cached PNG for key if it's still within chartCacheTTL,
+// otherwise calls render and caches the result.
+func renderCachedChart(key chartCacheKey, render func() ([]byte, error)) ([]byte, error) {
+	chartCacheMu.Lock()
+	if entry, ok := chartCacheStore[key]; ok && time.Since(entry.renderedAt) < chartCacheTTL {
+		chartCacheMu.Unlock()
+		return entry.png, nil
+	}
+	chartCacheMu.Unlock()
+
+	data, err := render()
+	if err != nil {
+		return nil, err
+	}
+
+	chartCacheMu.Lock()
+	chartCacheStore[key] = chartCacheEntry{png: data, renderedAt: time.Now()}
+	chartCacheMu.Unlock()
+	return data, nil
+}
+
+// bucketStart rounds down to the granularity a window's chart is cached at: hourly for
+// 24h, daily for 7d/30d. Within a bucket, a cached chart is still re-rendered once it is
+// older than chartCacheTTL.
+func bucketStart(windowLabel string, now time.Time) time.Time {
+	if windowLabel == "24h" {
+		return now.Truncate(time.Hour)
+	}
+	year, month, day := now.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+}
+
+// HourlyPoint is one point of an hourly-bucketed usage series.
+type HourlyPoint struct {
+	Hour time.Time
+	Avg  float64
+}
+
+// fetchHourlyBuckets averages usage per hour for venue since since, for the /stats 24h line chart.
+func fetchHourlyBuckets(pool *pgxpool.Pool, venue string, since time.Time) ([]HourlyPoint, error) {
+	rows, err := pool.Query(context.Background(), `
+        SELECT date_trunc('hour', timestamp) AS bucket, AVG(percentage)
+        FROM pool_usage
+        WHERE venue = $1 AND timestamp >= $2
+        GROUP BY bucket
+        ORDER BY bucket`, venue, since)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching hourly buckets: %v", err)
+	}
+	defer rows.Close()
+
+	var points []HourlyPoint
+	for rows.Next() {
+		var p HourlyPoint
+		if err := rows.Scan(&p.Hour, &p.Avg); err != nil {
+			return nil, fmt.Errorf("error scanning hourly bucket: %v", err)
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// HeatmapCell is one (day-of-week, hour-of-day) average usage reading.
+type HeatmapCell struct {
+	DayOfWeek int // 0 = Sunday, matching Postgres EXTRACT(DOW ...)
+	Hour      int
+	Avg       float64
+}
+
+// fetchHeatmapBuckets averages usage per (day-of-week, hour-of-day) for venue since since,
+// for the /stats 7d/30d heat-map.
+func fetchHeatmapBuckets(pool *pgxpool.Pool, venue string, since time.Time) ([]HeatmapCell, error) {
+	rows, err := pool.Query(context.Background(), `
+        SELECT EXTRACT(DOW FROM timestamp)::int, EXTRACT(HOUR FROM timestamp)::int, AVG(percentage)
+        FROM pool_usage
+        WHERE venue = $1 AND timestamp >= $2
+        GROUP BY 1, 2
+        ORDER BY 1, 2`, venue, since)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching heatmap buckets: %v", err)
+	}
+	defer rows.Close()
+
+	var cells []HeatmapCell
+	for rows.Next() {
+		var c HeatmapCell
+		if err := rows.Scan(&c.DayOfWeek, &c.Hour, &c.Avg); err != nil {
+			return nil, fmt.Errorf("error scanning heatmap bucket: %v", err)
+		}
+		cells = append(cells, c)
+	}
+	return cells, rows.Err()
+}
+
+// renderLineChart draws an hour-by-hour usage line chart and returns it as PNG bytes.
+func renderLineChart(venue string, points []HourlyPoint) ([]byte, error) {
+	xs := make([]time.Time, len(points))
+	ys := make([]float64, len(points))
+	for i, p := range points {
+		xs[i] = p.Hour
+		ys[i] = p.Avg
+	}
+
+	graph := chart.Chart{
+		Title: fmt.Sprintf("%s usage - last 24h", venue),
+		Series: []chart.Series{
+			chart.TimeSeries{
+				XValues: xs,
+				YValues: ys,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := graph.Render(chart.PNG, &buf); err != nil {
+		return nil, fmt.Errorf("error rendering line chart: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderHeatmap draws a day-of-week x hour-of-day grid, shaded from light (quiet) to
+// dark (busy), and returns it as PNG bytes.
+func renderHeatmap(venue string, cells []HeatmapCell) ([]byte, error) {
+	const cellSize = 24
+	const marginLeft = 40
+	const marginTop = 20
+	width := marginLeft + 24*cellSize
+	height := marginTop + 7*cellSize
+
+	var grid [7][24]float64
+	var maxAvg float64
+	for _, c := range cells {
+		if c.DayOfWeek < 0 || c.DayOfWeek > 6 || c.Hour < 0 || c.Hour > 23 {
+			continue
+		}
+		grid[c.DayOfWeek][c.Hour] = c.Avg
+		if c.Avg > maxAvg {
+			maxAvg = c.Avg
+		}
+	}
+	if maxAvg == 0 {
+		maxAvg = 1
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	drawFilledRect(img, 0, 0, width, height, color.White)
+
+	for day := 0; day < 7; day++ {
+		for hour := 0; hour < 24; hour++ {
+			intensity := grid[day][hour] / maxAvg
+			shade := color.RGBA{
+				R: uint8(255 - 180*intensity),
+				G: uint8(255 - 220*intensity),
+				B: 255,
+				A: 255,
+			}
+			x0 := marginLeft + hour*cellSize
+			y0 := marginTop + day*cellSize
+			drawFilledRect(img, x0, y0, x0+cellSize, y0+cellSize, shade)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("error encoding heatmap: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// drawFilledRect fills [x0,y0)-[x1,y1) with c.
+func drawFilledRect(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			img.Set(x, y, c)
+		}
+	}
+}
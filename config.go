@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the fully resolved configuration for a pool-worker process: a config.yaml
+// file, if given, read first, then overridden field-by-field by environment variables.
+type Config struct {
+	DatabaseURL           string
+	TelegramBotToken      string
+	TelegramChatID        string
+	TelegramWebhookURL    string
+	TelegramWebhookSecret string
+	TelegramWebhookAddr   string
+	TelegramAdminChatIDs  []string
+	PollInterval          time.Duration
+	Scrapers              []ScraperConfig
+}
+
+// yamlConfig mirrors config.yaml's shape before environment overrides and duration
+// parsing are applied.
+type yamlConfig struct {
+	DatabaseURL           string          `yaml:"database_url"`
+	TelegramBotToken      string          `yaml:"telegram_bot_token"`
+	TelegramChatID        string          `yaml:"telegram_chat_id"`
+	TelegramWebhookURL    string          `yaml:"telegram_webhook_url"`
+	TelegramWebhookSecret string          `yaml:"telegram_webhook_secret"`
+	TelegramWebhookAddr   string          `yaml:"telegram_webhook_addr"`
+	TelegramAdminChatIDs  []string        `yaml:"telegram_admin_chat_ids"`
+	PollInterval          string          `yaml:"poll_interval"`
+	Scrapers              []ScraperConfig `yaml:"scrapers"`
+}
+
+// LoadConfig reads path (if non-empty) as a YAML config file, applies environment
+// variable overrides on top, and returns the resolved Config. It fails fast with a
+// single error listing every missing required field, rather than stopping at the
+// first one.
+func LoadConfig(path string) (*Config, error) {
+	raw := yamlConfig{PollInterval: defaultPollInterval.String()}
+
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading config file %s: %v", path, err)
+		}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("error parsing config file %s: %v", path, err)
+		}
+	}
+
+	applyEnvOverrides(&raw)
+
+	interval, err := time.ParseDuration(raw.PollInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid poll_interval %q: %v", raw.PollInterval, err)
+	}
+
+	cfg := &Config{
+		DatabaseURL:           raw.DatabaseURL,
+		TelegramBotToken:      raw.TelegramBotToken,
+		TelegramChatID:        raw.TelegramChatID,
+		TelegramWebhookURL:    raw.TelegramWebhookURL,
+		TelegramWebhookSecret: raw.TelegramWebhookSecret,
+		TelegramWebhookAddr:   raw.TelegramWebhookAddr,
+		TelegramAdminChatIDs:  raw.TelegramAdminChatIDs,
+		PollInterval:          interval,
+		Scrapers:              raw.Scrapers,
+	}
+	if len(cfg.Scrapers) == 0 {
+		cfg.Scrapers = defaultScraperConfigs()
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyEnvOverrides overwrites any raw field whose environment variable is set.
+func applyEnvOverrides(raw *yamlConfig) {
+	if v := os.Getenv("DATABASE_URL"); v != "" {
+		raw.DatabaseURL = v
+	}
+	if v := os.Getenv("TELEGRAM_BOT_TOKEN"); v != "" {
+		raw.TelegramBotToken = v
+	}
+	if v := os.Getenv("TELEGRAM_CHAT_ID"); v != "" {
+		raw.TelegramChatID = v
+	}
+	if v := os.Getenv("TELEGRAM_WEBHOOK_URL"); v != "" {
+		raw.TelegramWebhookURL = v
+	}
+	if v := os.Getenv("TELEGRAM_WEBHOOK_SECRET"); v != "" {
+		raw.TelegramWebhookSecret = v
+	}
+	if v := os.Getenv("TELEGRAM_WEBHOOK_ADDR"); v != "" {
+		raw.TelegramWebhookAddr = v
+	}
+	if v := os.Getenv("TELEGRAM_ADMIN_CHAT_IDS"); v != "" {
+		raw.TelegramAdminChatIDs = strings.Split(v, ",")
+	}
+	if v := os.Getenv("POLL_INTERVAL"); v != "" {
+		raw.PollInterval = v
+	}
+}
+
+// validate checks every required field at once, so operators see the full list of
+// what's missing instead of fixing one field per run.
+func (c *Config) validate() error {
+	var missing []string
+	if c.DatabaseURL == "" {
+		missing = append(missing, "database_url (DATABASE_URL)")
+	}
+	if c.TelegramBotToken == "" {
+		missing = append(missing, "telegram_bot_token (TELEGRAM_BOT_TOKEN)")
+	}
+	if c.TelegramChatID == "" {
+		missing = append(missing, "telegram_chat_id (TELEGRAM_CHAT_ID)")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required config fields: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
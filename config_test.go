@@ -0,0 +1,163 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestApplyEnvOverrides(t *testing.T) {
+	envVars := []string{
+		"DATABASE_URL", "TELEGRAM_BOT_TOKEN", "TELEGRAM_CHAT_ID",
+		"TELEGRAM_WEBHOOK_URL", "TELEGRAM_WEBHOOK_SECRET", "TELEGRAM_WEBHOOK_ADDR",
+		"TELEGRAM_ADMIN_CHAT_IDS", "POLL_INTERVAL",
+	}
+	for _, v := range envVars {
+		os.Unsetenv(v)
+	}
+	t.Cleanup(func() {
+		for _, v := range envVars {
+			os.Unsetenv(v)
+		}
+	})
+
+	raw := yamlConfig{
+		DatabaseURL:      "postgres://from-yaml",
+		TelegramBotToken: "yaml-token",
+		PollInterval:     "5m",
+	}
+
+	os.Setenv("DATABASE_URL", "postgres://from-env")
+	os.Setenv("TELEGRAM_ADMIN_CHAT_IDS", "1,2,3")
+	os.Setenv("POLL_INTERVAL", "30s")
+
+	applyEnvOverrides(&raw)
+
+	if raw.DatabaseURL != "postgres://from-env" {
+		t.Errorf("DatabaseURL = %q, want env override to win", raw.DatabaseURL)
+	}
+	if raw.TelegramBotToken != "yaml-token" {
+		t.Errorf("TelegramBotToken = %q, want yaml value preserved when env unset", raw.TelegramBotToken)
+	}
+	if want := []string{"1", "2", "3"}; !stringSlicesEqual(raw.TelegramAdminChatIDs, want) {
+		t.Errorf("TelegramAdminChatIDs = %v, want %v", raw.TelegramAdminChatIDs, want)
+	}
+	if raw.PollInterval != "30s" {
+		t.Errorf("PollInterval = %q, want env override to win", raw.PollInterval)
+	}
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{
+			name: "all required fields present",
+			cfg: Config{
+				DatabaseURL:      "postgres://x",
+				TelegramBotToken: "token",
+				TelegramChatID:   "123",
+			},
+			wantErr: "",
+		},
+		{
+			name:    "all required fields missing",
+			cfg:     Config{},
+			wantErr: "missing required config fields: database_url (DATABASE_URL), telegram_bot_token (TELEGRAM_BOT_TOKEN), telegram_chat_id (TELEGRAM_CHAT_ID)",
+		},
+		{
+			name: "only chat id missing",
+			cfg: Config{
+				DatabaseURL:      "postgres://x",
+				TelegramBotToken: "token",
+			},
+			wantErr: "missing required config fields: telegram_chat_id (TELEGRAM_CHAT_ID)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("validate() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	if _, err := LoadConfig("/nonexistent/config.yaml"); err == nil {
+		t.Error("LoadConfig() with missing file = nil error, want error")
+	}
+}
+
+func TestLoadConfigDefaultPollInterval(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/config.yaml"
+	if err := os.WriteFile(path, []byte(`
+database_url: postgres://x
+telegram_bot_token: token
+telegram_chat_id: "123"
+`), 0o644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	for _, v := range []string{"DATABASE_URL", "TELEGRAM_BOT_TOKEN", "TELEGRAM_CHAT_ID", "POLL_INTERVAL"} {
+		os.Unsetenv(v)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.PollInterval != defaultPollInterval {
+		t.Errorf("PollInterval = %v, want default %v", cfg.PollInterval, defaultPollInterval)
+	}
+	if len(cfg.Scrapers) != 1 || cfg.Scrapers[0].VenueID != defaultVenueID {
+		t.Errorf("Scrapers = %v, want single default scraper for %q", cfg.Scrapers, defaultVenueID)
+	}
+}
+
+func TestLoadConfigPollIntervalOverride(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/config.yaml"
+	if err := os.WriteFile(path, []byte(`
+database_url: postgres://x
+telegram_bot_token: token
+telegram_chat_id: "123"
+poll_interval: 2m
+`), 0o644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	os.Setenv("POLL_INTERVAL", "10s")
+	t.Cleanup(func() { os.Unsetenv("POLL_INTERVAL") })
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.PollInterval != 10*time.Second {
+		t.Errorf("PollInterval = %v, want env override of 10s", cfg.PollInterval)
+	}
+}
@@ -2,19 +2,23 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
-	"regexp"
-	"strconv"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// defaultPollInterval is used when neither poll_interval in config.yaml nor POLL_INTERVAL is set.
+const defaultPollInterval = 5 * time.Minute
+
 // Function to send a message to a Telegram bot
 func sendTelegramMessage(botToken, chatID, message string) error {
 	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", botToken)
@@ -38,6 +42,26 @@ func sendTelegramMessage(botToken, chatID, message string) error {
 	return nil
 }
 
+// defaultVenueID is used for rows that predate the multi-venue schema.
+const defaultVenueID = "lazdynai"
+
+// defaultConfigPath is tried when --config is not passed, so a plain `config.yaml`
+// in the working directory just works without any flags or env vars.
+const defaultConfigPath = "config.yaml"
+
+// defaultScraperConfigs is used when the config defines no scrapers, preserving
+// the original single-venue behavior out of the box.
+func defaultScraperConfigs() []ScraperConfig {
+	return []ScraperConfig{
+		{
+			VenueID:  defaultVenueID,
+			URL:      "https://www.lazdynubaseinas.eu/",
+			Strategy: "regex",
+			Pattern:  `Šiuo metu esantis Lazdynų baseino ir sporto klubo užimtumas: <span style="font-size:\d+\.\d+rem;">(\d+)%</span>`,
+		},
+	}
+}
+
 // Initialize the database, create the table if it doesn't exist
 func initDb(pool *pgxpool.Pool) error {
 	createTableQuery := `
@@ -51,49 +75,25 @@ func initDb(pool *pgxpool.Pool) error {
 	if err != nil {
 		return fmt.Errorf("error creating table: %v", err)
 	}
-	return nil
-}
 
-// Fetch pool usage from the website
-func fetchPoolUsage() (int, error) {
-	url := "https://www.lazdynubaseinas.eu/"
-
-	// Send an HTTP GET request
-	resp, err := http.Get(url)
-	if err != nil {
-		return 0, fmt.Errorf("error fetching the URL: %v", err)
+	// Migration: add the venue column, backfilling existing rows to the original
+	// single-venue scraper, and index lookups by (venue, timestamp).
+	migrateQuery := `
+    ALTER TABLE pool_usage ADD COLUMN IF NOT EXISTS venue TEXT NOT NULL DEFAULT '` + defaultVenueID + `';
+    CREATE INDEX IF NOT EXISTS pool_usage_venue_timestamp_idx ON pool_usage (venue, timestamp);`
+	if _, err := pool.Exec(context.Background(), migrateQuery); err != nil {
+		return fmt.Errorf("error migrating pool_usage table: %v", err)
 	}
-	defer resp.Body.Close()
 
-	// Read the response body
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return 0, fmt.Errorf("error reading the response body: %v", err)
-	}
-
-	// Convert the response body to a string
-	htmlContent := string(body)
-
-	// Use a regular expression to find the usage percentage
-	re := regexp.MustCompile(`Šiuo metu esantis Lazdynų baseino ir sporto klubo užimtumas: <span style="font-size:\d+\.\d+rem;">(\d+)%</span>`)
-	matches := re.FindStringSubmatch(htmlContent)
-	if len(matches) > 1 {
-		// Convert the matched percentage to an integer
-		usage, err := strconv.Atoi(matches[1])
-		if err != nil {
-			return 0, fmt.Errorf("error converting percentage: %v", err)
-		}
-		return usage, nil
-	}
-	return 0, fmt.Errorf("could not find usage percentage")
+	return nil
 }
 
 // Save the pool usage to the database
-func saveToDatabase(pool *pgxpool.Pool, poolUsage int) error {
+func saveToDatabase(pool *pgxpool.Pool, venue string, poolUsage int) error {
 	// Insert the data into the table
 	_, err := pool.Exec(context.Background(),
-		"INSERT INTO pool_usage (timestamp, percentage) VALUES ($1, $2)",
-		time.Now(), poolUsage)
+		"INSERT INTO pool_usage (timestamp, venue, percentage) VALUES ($1, $2, $3)",
+		time.Now(), venue, poolUsage)
 	if err != nil {
 		return fmt.Errorf("error inserting data into database: %v", err)
 	}
@@ -102,21 +102,32 @@ func saveToDatabase(pool *pgxpool.Pool, poolUsage int) error {
 }
 
 func main() {
-	// Get the database connection parameters and Telegram bot credentials from environment variables
-	dbURL := os.Getenv("DATABASE_URL")
-	botToken := os.Getenv("TELEGRAM_BOT_TOKEN")
-	chatID := os.Getenv("TELEGRAM_CHAT_ID")
+	once := flag.Bool("once", false, "fetch the pool usage a single time and exit, instead of running as a daemon")
+	configPath := flag.String("config", "", "path to the config.yaml file (defaults to ./config.yaml if present)")
+	flag.Parse()
+
+	path := *configPath
+	if path == "" {
+		if _, err := os.Stat(defaultConfigPath); err == nil {
+			path = defaultConfigPath
+		}
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		log.Fatalf("Error loading config: %v\n", err)
+	}
 
-	if dbURL == "" || botToken == "" || chatID == "" {
-		log.Fatal("DATABASE_URL, TELEGRAM_BOT_TOKEN, and TELEGRAM_CHAT_ID environment variables must be set")
+	scrapers, err := NewScraperRegistry(cfg.Scrapers)
+	if err != nil {
+		log.Fatalf("Error building scrapers: %v\n", err)
 	}
 
 	// Create a connection pool
-	config, err := pgxpool.ParseConfig(dbURL)
+	poolConfig, err := pgxpool.ParseConfig(cfg.DatabaseURL)
 	if err != nil {
 		log.Fatalf("Unable to parse DATABASE_URL: %v\n", err)
 	}
-	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
 		log.Fatalf("Unable to create connection pool: %v\n", err)
 	}
@@ -127,29 +138,24 @@ func main() {
 	if err != nil {
 		log.Fatalf("Error initializing the database: %v\n", err)
 	}
+	if err := initAlertsTable(pool); err != nil {
+		log.Fatalf("Error initializing the database: %v\n", err)
+	}
 
-	// Fetch the pool usage
-	usage, err := fetchPoolUsage()
-	if err != nil {
-		log.Printf("Error fetching pool usage: %v\n", err)
+	if *once {
+		poll(context.Background(), pool, scrapers, cfg)
 		return
 	}
-	log.Printf("Current swimming pool usage: %d%%\n", usage)
 
-	// Save the result to the database
-	err = saveToDatabase(pool, usage)
-	if err != nil {
-		log.Printf("Error saving to database: %v\n", err)
-	} else {
-		log.Println("Data successfully saved to the database.")
-	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	// Send the result to the Telegram bot
-	message := fmt.Sprintf("Current swimming pool usage is %d%%", usage)
-	err = sendTelegramMessage(botToken, chatID, message)
-	if err != nil {
-		log.Printf("Error sending message to Telegram: %v\n", err)
-	} else {
-		log.Println("Message successfully sent to Telegram.")
-	}
+	go func() {
+		if err := runTelegramBot(ctx, pool, scrapers, cfg); err != nil {
+			log.Printf("Telegram bot stopped: %v\n", err)
+		}
+	}()
+
+	log.Printf("Starting pool-worker daemon, polling every %s\n", cfg.PollInterval)
+	runLoop(ctx, pool, scrapers, cfg, cfg.PollInterval)
 }
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// runLoop polls every registered scraper on interval until ctx is cancelled, saving each
+// reading and evaluating alert subscriptions against it.
+func runLoop(ctx context.Context, pool *pgxpool.Pool, scrapers *ScraperRegistry, cfg *Config, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	poll(ctx, pool, scrapers, cfg)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Shutting down: context cancelled.")
+			return
+		case <-ticker.C:
+			poll(ctx, pool, scrapers, cfg)
+		}
+	}
+}
+
+// poll fetches the current usage for every scraper, persists it and notifies any
+// matching subscriptions.
+func poll(ctx context.Context, pool *pgxpool.Pool, scrapers *ScraperRegistry, cfg *Config) {
+	for _, scraper := range scrapers.All() {
+		venue := scraper.Name()
+
+		usage, err := scraper.Fetch(ctx)
+		if err != nil {
+			log.Printf("Error fetching pool usage for venue %q: %v\n", venue, err)
+			continue
+		}
+		log.Printf("Current usage for venue %q: %d%%\n", venue, usage)
+
+		if err := saveToDatabase(pool, venue, usage); err != nil {
+			log.Printf("Error saving to database for venue %q: %v\n", venue, err)
+		}
+
+		evaluateAlerts(pool, cfg.TelegramBotToken, venue, usage)
+	}
+}
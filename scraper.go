@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Scraper knows how to fetch the current occupancy percentage for one venue.
+type Scraper interface {
+	Name() string
+	Fetch(ctx context.Context) (int, error)
+}
+
+// ScraperConfig describes one entry of the scrapers config file.
+type ScraperConfig struct {
+	VenueID  string `yaml:"venue_id"`
+	URL      string `yaml:"url"`
+	Strategy string `yaml:"strategy"` // "regex", "css-selector" or "jsonpath"
+	Pattern  string `yaml:"pattern"`  // regex pattern, css selector, or dotted JSON field path
+}
+
+// ScraperRegistry holds the set of scrapers active for this deployment, keyed by venue id.
+type ScraperRegistry struct {
+	scrapers map[string]Scraper
+	order    []string
+}
+
+// NewScraperRegistry builds a registry from config entries, failing on unknown strategies
+// or duplicate venue ids.
+func NewScraperRegistry(configs []ScraperConfig) (*ScraperRegistry, error) {
+	reg := &ScraperRegistry{scrapers: make(map[string]Scraper, len(configs))}
+	for _, c := range configs {
+		if _, exists := reg.scrapers[c.VenueID]; exists {
+			return nil, fmt.Errorf("duplicate venue_id %q in scraper config", c.VenueID)
+		}
+		scraper, err := buildScraper(c)
+		if err != nil {
+			return nil, err
+		}
+		reg.scrapers[c.VenueID] = scraper
+		reg.order = append(reg.order, c.VenueID)
+	}
+	return reg, nil
+}
+
+// Get returns the scraper registered for venueID, if any.
+func (r *ScraperRegistry) Get(venueID string) (Scraper, bool) {
+	s, ok := r.scrapers[venueID]
+	return s, ok
+}
+
+// All returns every registered scraper, in config order.
+func (r *ScraperRegistry) All() []Scraper {
+	scrapers := make([]Scraper, 0, len(r.order))
+	for _, venueID := range r.order {
+		scrapers = append(scrapers, r.scrapers[venueID])
+	}
+	return scrapers
+}
+
+func buildScraper(c ScraperConfig) (Scraper, error) {
+	switch c.Strategy {
+	case "regex":
+		return newRegexScraper(c.VenueID, c.URL, c.Pattern)
+	case "css-selector":
+		return newCSSSelectorScraper(c.VenueID, c.URL, c.Pattern), nil
+	case "jsonpath":
+		return newJSONPathScraper(c.VenueID, c.URL, c.Pattern), nil
+	default:
+		return nil, fmt.Errorf("unknown scraper strategy %q for venue %q", c.Strategy, c.VenueID)
+	}
+}
+
+// httpGetBody fetches url and returns the response body, shared by every strategy.
+// The request honors ctx so an in-flight fetch can be cancelled on shutdown.
+func httpGetBody(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error building request for %s: %v", url, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body from %s: %v", url, err)
+	}
+	return body, nil
+}
+
+// regexScraper extracts the usage percentage via a regular expression with one capture group.
+type regexScraper struct {
+	venueID string
+	url     string
+	re      *regexp.Regexp
+}
+
+func newRegexScraper(venueID, url, pattern string) (*regexScraper, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex for venue %q: %v", venueID, err)
+	}
+	return &regexScraper{venueID: venueID, url: url, re: re}, nil
+}
+
+func (s *regexScraper) Name() string { return s.venueID }
+
+func (s *regexScraper) Fetch(ctx context.Context) (int, error) {
+	body, err := httpGetBody(ctx, s.url)
+	if err != nil {
+		return 0, err
+	}
+
+	matches := s.re.FindStringSubmatch(string(body))
+	if len(matches) < 2 {
+		return 0, fmt.Errorf("could not find usage percentage for venue %q", s.venueID)
+	}
+	return strconv.Atoi(matches[1])
+}
+
+// cssSelectorScraper extracts the usage percentage from the text content of a CSS selector.
+type cssSelectorScraper struct {
+	venueID  string
+	url      string
+	selector string
+}
+
+func newCSSSelectorScraper(venueID, url, selector string) *cssSelectorScraper {
+	return &cssSelectorScraper{venueID: venueID, url: url, selector: selector}
+}
+
+func (s *cssSelectorScraper) Name() string { return s.venueID }
+
+func (s *cssSelectorScraper) Fetch(ctx context.Context) (int, error) {
+	body, err := httpGetBody(ctx, s.url)
+	if err != nil {
+		return 0, err
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("error parsing HTML for venue %q: %v", s.venueID, err)
+	}
+
+	selection := doc.Find(s.selector).First()
+	if selection.Length() == 0 {
+		return 0, fmt.Errorf("selector %q matched nothing for venue %q", s.selector, s.venueID)
+	}
+
+	re := regexp.MustCompile(`\d+`)
+	match := re.FindString(selection.Text())
+	if match == "" {
+		return 0, fmt.Errorf("no percentage found in selector text for venue %q", s.venueID)
+	}
+	return strconv.Atoi(match)
+}
+
+// jsonPathScraper extracts the usage percentage from a JSON endpoint using a dotted field path.
+type jsonPathScraper struct {
+	venueID string
+	url     string
+	path    string
+}
+
+func newJSONPathScraper(venueID, url, path string) *jsonPathScraper {
+	return &jsonPathScraper{venueID: venueID, url: url, path: path}
+}
+
+func (s *jsonPathScraper) Name() string { return s.venueID }
+
+func (s *jsonPathScraper) Fetch(ctx context.Context) (int, error) {
+	body, err := httpGetBody(ctx, s.url)
+	if err != nil {
+		return 0, err
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return 0, fmt.Errorf("error parsing JSON for venue %q: %v", s.venueID, err)
+	}
+
+	value, err := lookupJSONPath(doc, s.path)
+	if err != nil {
+		return 0, fmt.Errorf("error resolving jsonpath %q for venue %q: %v", s.path, s.venueID, err)
+	}
+
+	switch v := value.(type) {
+	case float64:
+		return int(v), nil
+	case string:
+		return strconv.Atoi(v)
+	default:
+		return 0, fmt.Errorf("unexpected type %T at jsonpath %q for venue %q", value, s.path, s.venueID)
+	}
+}
+
+// lookupJSONPath resolves a dotted field path (e.g. "data.occupancy.percentage")
+// against a decoded JSON document.
+func lookupJSONPath(doc map[string]interface{}, path string) (interface{}, error) {
+	var current interface{} = doc
+	for _, field := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("field %q is not an object", field)
+		}
+		value, ok := m[field]
+		if !ok {
+			return nil, fmt.Errorf("field %q not found", field)
+		}
+		current = value
+	}
+	return current, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import "testing"
+
+func TestLookupJSONPath(t *testing.T) {
+	doc := map[string]interface{}{
+		"data": map[string]interface{}{
+			"occupancy": map[string]interface{}{
+				"percentage": 42.0,
+			},
+		},
+	}
+
+	t.Run("resolves nested path", func(t *testing.T) {
+		v, err := lookupJSONPath(doc, "data.occupancy.percentage")
+		if err != nil {
+			t.Fatalf("lookupJSONPath() error = %v", err)
+		}
+		if v != 42.0 {
+			t.Errorf("lookupJSONPath() = %v, want 42.0", v)
+		}
+	})
+
+	t.Run("missing field errors", func(t *testing.T) {
+		if _, err := lookupJSONPath(doc, "data.missing"); err == nil {
+			t.Error("lookupJSONPath() error = nil, want error for missing field")
+		}
+	})
+
+	t.Run("field not an object errors", func(t *testing.T) {
+		if _, err := lookupJSONPath(doc, "data.occupancy.percentage.extra"); err == nil {
+			t.Error("lookupJSONPath() error = nil, want error when descending into a non-object")
+		}
+	})
+}
+
+func TestNewScraperRegistryDuplicateVenue(t *testing.T) {
+	configs := []ScraperConfig{
+		{VenueID: "lazdynai", URL: "https://example.com/a", Strategy: "regex", Pattern: `(\d+)%`},
+		{VenueID: "lazdynai", URL: "https://example.com/b", Strategy: "regex", Pattern: `(\d+)%`},
+	}
+
+	_, err := NewScraperRegistry(configs)
+	if err == nil {
+		t.Fatal("NewScraperRegistry() error = nil, want error for duplicate venue_id")
+	}
+	const want = `duplicate venue_id "lazdynai" in scraper config`
+	if err.Error() != want {
+		t.Errorf("NewScraperRegistry() error = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestNewScraperRegistryAll(t *testing.T) {
+	configs := []ScraperConfig{
+		{VenueID: "lazdynai", URL: "https://example.com/a", Strategy: "regex", Pattern: `(\d+)%`},
+		{VenueID: "other", URL: "https://example.com/b", Strategy: "regex", Pattern: `(\d+)%`},
+	}
+
+	reg, err := NewScraperRegistry(configs)
+	if err != nil {
+		t.Fatalf("NewScraperRegistry() error = %v", err)
+	}
+	all := reg.All()
+	if len(all) != 2 || all[0].Name() != "lazdynai" || all[1].Name() != "other" {
+		t.Errorf("All() = %v, want [lazdynai, other] in config order", all)
+	}
+	if _, ok := reg.Get("missing"); ok {
+		t.Error("Get() ok = true for unregistered venue, want false")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// StatsSummary aggregates pool_usage readings over a time window.
+type StatsSummary struct {
+	Count       int
+	Min         int
+	Max         int
+	Avg         float64
+	P50         float64
+	P90         float64
+	BusiestHour int
+}
+
+// statsWindows maps the /stats argument to how far back to look.
+var statsWindows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// parseStatsWindow resolves a /stats argument (e.g. "24h", "7d", "30d") to a duration,
+// defaulting to 24h when arg is empty.
+func parseStatsWindow(arg string) (string, time.Duration, error) {
+	if arg == "" {
+		arg = "24h"
+	}
+	window, ok := statsWindows[arg]
+	if !ok {
+		return "", 0, fmt.Errorf("unknown window %q (expected 24h, 7d or 30d)", arg)
+	}
+	return arg, window, nil
+}
+
+// fetchStats computes min/max/avg/percentiles and the busiest hour-of-day for venue's
+// readings taken since since.
+func fetchStats(pool *pgxpool.Pool, venue string, since time.Time) (StatsSummary, error) {
+	var s StatsSummary
+
+	row := pool.QueryRow(context.Background(), `
+        SELECT
+            COUNT(*),
+            COALESCE(MIN(percentage), 0),
+            COALESCE(MAX(percentage), 0),
+            COALESCE(AVG(percentage), 0),
+            COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY percentage), 0),
+            COALESCE(PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY percentage), 0)
+        FROM pool_usage
+        WHERE venue = $1 AND timestamp >= $2`, venue, since)
+	if err := row.Scan(&s.Count, &s.Min, &s.Max, &s.Avg, &s.P50, &s.P90); err != nil {
+		return s, fmt.Errorf("error computing stats: %v", err)
+	}
+
+	if s.Count == 0 {
+		return s, nil
+	}
+
+	hourRow := pool.QueryRow(context.Background(), `
+        SELECT EXTRACT(HOUR FROM timestamp)::int AS hour
+        FROM pool_usage
+        WHERE venue = $1 AND timestamp >= $2
+        GROUP BY hour
+        ORDER BY AVG(percentage) DESC
+        LIMIT 1`, venue, since)
+	if err := hourRow.Scan(&s.BusiestHour); err != nil {
+		return s, fmt.Errorf("error computing busiest hour: %v", err)
+	}
+
+	return s, nil
+}
+
+// formatStatsMessage renders a StatsSummary as a human-readable Telegram message.
+func formatStatsMessage(windowLabel string, s StatsSummary) string {
+	if s.Count == 0 {
+		return fmt.Sprintf("No readings recorded in the last %s.", windowLabel)
+	}
+	return fmt.Sprintf(
+		"Pool usage over the last %s (%d readings):\nmin %d%% / avg %.1f%% / max %d%%\np50 %.0f%% / p90 %.0f%%\nbusiest hour: %02d:00",
+		windowLabel, s.Count, s.Min, s.Avg, s.Max, s.P50, s.P90, s.BusiestHour,
+	)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseStatsWindow(t *testing.T) {
+	tests := []struct {
+		name       string
+		arg        string
+		wantLabel  string
+		wantWindow time.Duration
+		wantErr    bool
+	}{
+		{"empty defaults to 24h", "", "24h", 24 * time.Hour, false},
+		{"24h", "24h", "24h", 24 * time.Hour, false},
+		{"7d", "7d", "7d", 7 * 24 * time.Hour, false},
+		{"30d", "30d", "30d", 30 * 24 * time.Hour, false},
+		{"unknown window errors", "1y", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			label, window, err := parseStatsWindow(tt.arg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseStatsWindow(%q) error = nil, want error", tt.arg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseStatsWindow(%q) error = %v", tt.arg, err)
+			}
+			if label != tt.wantLabel || window != tt.wantWindow {
+				t.Errorf("parseStatsWindow(%q) = (%q, %v), want (%q, %v)", tt.arg, label, window, tt.wantLabel, tt.wantWindow)
+			}
+		})
+	}
+}
+
+func TestFormatStatsMessageNoReadings(t *testing.T) {
+	got := formatStatsMessage("24h", StatsSummary{Count: 0})
+	want := "No readings recorded in the last 24h."
+	if got != want {
+		t.Errorf("formatStatsMessage() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,444 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// defaultAlertCooldown is used for subscriptions created via /subscribe.
+const defaultAlertCooldown = 30 * time.Minute
+
+// Update is a single item returned by the Telegram getUpdates API / delivered to a webhook.
+type Update struct {
+	UpdateID int      `json:"update_id"`
+	Message  *Message `json:"message"`
+}
+
+// Message is the subset of the Telegram Message object the bot cares about.
+type Message struct {
+	Chat Chat   `json:"chat"`
+	Text string `json:"text"`
+}
+
+// Chat identifies who a message came from/should be sent to.
+type Chat struct {
+	ID int64 `json:"id"`
+}
+
+// getUpdatesResponse wraps the Telegram getUpdates API response envelope.
+type getUpdatesResponse struct {
+	OK     bool     `json:"ok"`
+	Result []Update `json:"result"`
+}
+
+// getUpdates long-polls Telegram for updates with id > offset.
+func getUpdates(botToken string, offset int) ([]Update, error) {
+	url := fmt.Sprintf("https://api.telegram.org/bot%s/getUpdates?offset=%d&timeout=30", botToken, offset)
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("error polling for updates: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading getUpdates response: %v", err)
+	}
+
+	var parsed getUpdatesResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("error decoding getUpdates response: %v", err)
+	}
+	if !parsed.OK {
+		return nil, fmt.Errorf("telegram getUpdates returned not-ok response: %s", string(body))
+	}
+	return parsed.Result, nil
+}
+
+// sendTelegramPhoto uploads png to chatID via a multipart/form-data POST to sendPhoto,
+// captioned with caption.
+func sendTelegramPhoto(botToken, chatID string, png []byte, caption string) error {
+	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendPhoto", botToken)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	if err := writer.WriteField("chat_id", chatID); err != nil {
+		return fmt.Errorf("error building photo request: %v", err)
+	}
+	if err := writer.WriteField("caption", caption); err != nil {
+		return fmt.Errorf("error building photo request: %v", err)
+	}
+	part, err := writer.CreateFormFile("photo", "stats.png")
+	if err != nil {
+		return fmt.Errorf("error building photo request: %v", err)
+	}
+	if _, err := part.Write(png); err != nil {
+		return fmt.Errorf("error building photo request: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error building photo request: %v", err)
+	}
+
+	resp, err := http.Post(url, writer.FormDataContentType(), &body)
+	if err != nil {
+		return fmt.Errorf("failed to send photo: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("telegram API responded with status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// setWebhook registers webhookURL with Telegram so updates are POSTed to it instead
+// of requiring long polling.
+func setWebhook(botToken, webhookURL, secret string) error {
+	url := fmt.Sprintf("https://api.telegram.org/bot%s/setWebhook", botToken)
+	reqBody := fmt.Sprintf("url=%s&secret_token=%s", webhookURL, secret)
+
+	resp, err := http.Post(url, "application/x-www-form-urlencoded", strings.NewReader(reqBody))
+	if err != nil {
+		return fmt.Errorf("error registering webhook: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("telegram setWebhook responded with status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// runTelegramBot consumes Telegram updates and dispatches bot commands until ctx is
+// cancelled. It uses webhook mode when cfg.TelegramWebhookURL is set, long polling otherwise.
+func runTelegramBot(ctx context.Context, pool *pgxpool.Pool, scrapers *ScraperRegistry, cfg *Config) error {
+	if cfg.TelegramWebhookURL != "" {
+		return runWebhookBot(ctx, pool, scrapers, cfg)
+	}
+	return runLongPollingBot(ctx, pool, scrapers, cfg)
+}
+
+// runLongPollingBot repeatedly calls getUpdates, tracking the offset so each update
+// is only processed once.
+func runLongPollingBot(ctx context.Context, pool *pgxpool.Pool, scrapers *ScraperRegistry, cfg *Config) error {
+	offset := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		updates, err := getUpdates(cfg.TelegramBotToken, offset)
+		if err != nil {
+			log.Printf("Error getting Telegram updates: %v\n", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			handleUpdate(pool, scrapers, cfg, update)
+		}
+	}
+}
+
+// runWebhookBot serves an HTTP endpoint that Telegram POSTs updates to. When
+// cfg.TelegramWebhookSecret is set, requests must carry it in the
+// X-Telegram-Bot-Api-Secret-Token header, which Telegram sends after setWebhook.
+func runWebhookBot(ctx context.Context, pool *pgxpool.Pool, scrapers *ScraperRegistry, cfg *Config) error {
+	if err := setWebhook(cfg.TelegramBotToken, cfg.TelegramWebhookURL, cfg.TelegramWebhookSecret); err != nil {
+		return fmt.Errorf("error setting up webhook: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/telegram/webhook", func(w http.ResponseWriter, r *http.Request) {
+		if cfg.TelegramWebhookSecret != "" && r.Header.Get("X-Telegram-Bot-Api-Secret-Token") != cfg.TelegramWebhookSecret {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var update Update
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		handleUpdate(pool, scrapers, cfg, update)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	addr := cfg.TelegramWebhookAddr
+	if addr == "" {
+		addr = ":8080"
+	}
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("error serving webhook: %v", err)
+	}
+	return nil
+}
+
+// handleUpdate dispatches a single update to the command handler, if it is a text command.
+func handleUpdate(pool *pgxpool.Pool, scrapers *ScraperRegistry, cfg *Config, update Update) {
+	if update.Message == nil || !strings.HasPrefix(update.Message.Text, "/") {
+		return
+	}
+	fromChatID := strconv.FormatInt(update.Message.Chat.ID, 10)
+	dispatchCommand(pool, scrapers, cfg, fromChatID, update.Message.Text)
+}
+
+// isAuthorized reports whether fromChatID may run write commands (/subscribe, /unsubscribe):
+// either the configured primary chat, or one of cfg.TelegramAdminChatIDs.
+func isAuthorized(fromChatID string, cfg *Config) bool {
+	if fromChatID == cfg.TelegramChatID {
+		return true
+	}
+	for _, admin := range cfg.TelegramAdminChatIDs {
+		if strings.TrimSpace(admin) == fromChatID {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatchCommand parses and runs a single bot command, replying to fromChatID.
+func dispatchCommand(pool *pgxpool.Pool, scrapers *ScraperRegistry, cfg *Config, fromChatID, text string) {
+	fields := strings.Fields(text)
+	command := fields[0]
+	args := fields[1:]
+
+	if command == "/stats" {
+		runStatsCommand(pool, scrapers, cfg, fromChatID, args)
+		return
+	}
+
+	var reply string
+	switch command {
+	case "/current":
+		reply = handleCurrentCommand(pool, scrapers, args)
+	case "/subscribe":
+		reply = handleSubscribeCommand(pool, scrapers, cfg, fromChatID, args)
+	case "/unsubscribe":
+		reply = handleUnsubscribeCommand(pool, cfg, fromChatID, args)
+	default:
+		return
+	}
+
+	if err := sendTelegramMessage(cfg.TelegramBotToken, fromChatID, reply); err != nil {
+		log.Printf("Error replying to chat %s: %v\n", fromChatID, err)
+	}
+}
+
+// resolveVenue validates arg (if given) against scrapers. When arg is omitted, it
+// defaults to the single registered venue; with more than one configured, an explicit
+// venue argument is required since there's no generally-correct default to pick.
+func resolveVenue(scrapers *ScraperRegistry, arg string) (string, error) {
+	if arg == "" {
+		all := scrapers.All()
+		if len(all) != 1 {
+			names := make([]string, len(all))
+			for i, s := range all {
+				names[i] = s.Name()
+			}
+			return "", fmt.Errorf("venue argument required (configured venues: %s)", strings.Join(names, ", "))
+		}
+		return all[0].Name(), nil
+	}
+	if _, ok := scrapers.Get(arg); !ok {
+		return "", fmt.Errorf("unknown venue %q", arg)
+	}
+	return arg, nil
+}
+
+func handleCurrentCommand(pool *pgxpool.Pool, scrapers *ScraperRegistry, args []string) string {
+	var venueArg string
+	if len(args) > 0 {
+		venueArg = args[0]
+	}
+	venue, err := resolveVenue(scrapers, venueArg)
+	if err != nil {
+		return err.Error()
+	}
+
+	var percentage int
+	var timestamp time.Time
+	err = pool.QueryRow(context.Background(),
+		"SELECT percentage, timestamp FROM pool_usage WHERE venue = $1 ORDER BY timestamp DESC LIMIT 1", venue).
+		Scan(&percentage, &timestamp)
+	if err != nil {
+		return fmt.Sprintf("No readings recorded yet for %s.", venue)
+	}
+	return fmt.Sprintf("%s usage is %d%% (as of %s)", venue, percentage, timestamp.Format(time.RFC3339))
+}
+
+// runStatsCommand replies to /stats with a text summary captioning a chart: an hourly
+// line for the 24h window, or a day-of-week x hour-of-day heat-map for 7d/30d.
+func runStatsCommand(pool *pgxpool.Pool, scrapers *ScraperRegistry, cfg *Config, fromChatID string, args []string) {
+	var windowArg, venueArg string
+	if len(args) > 0 {
+		windowArg = args[0]
+	}
+	if len(args) > 1 {
+		venueArg = args[1]
+	}
+
+	venue, err := resolveVenue(scrapers, venueArg)
+	if err != nil {
+		sendStatsError(cfg, fromChatID, err)
+		return
+	}
+
+	label, window, err := parseStatsWindow(windowArg)
+	if err != nil {
+		sendStatsError(cfg, fromChatID, err)
+		return
+	}
+
+	summary, err := fetchStats(pool, venue, time.Now().Add(-window))
+	if err != nil {
+		log.Printf("Error fetching stats: %v\n", err)
+		sendStatsError(cfg, fromChatID, fmt.Errorf("error computing stats"))
+		return
+	}
+	caption := fmt.Sprintf("%s: %s", venue, formatStatsMessage(label, summary))
+
+	if summary.Count == 0 {
+		if err := sendTelegramMessage(cfg.TelegramBotToken, fromChatID, caption); err != nil {
+			log.Printf("Error replying to chat %s: %v\n", fromChatID, err)
+		}
+		return
+	}
+
+	chartPNG, err := renderStatsChart(pool, venue, label, window)
+	if err != nil {
+		log.Printf("Error rendering stats chart: %v\n", err)
+		if err := sendTelegramMessage(cfg.TelegramBotToken, fromChatID, caption); err != nil {
+			log.Printf("Error replying to chat %s: %v\n", fromChatID, err)
+		}
+		return
+	}
+
+	if err := sendTelegramPhoto(cfg.TelegramBotToken, fromChatID, chartPNG, caption); err != nil {
+		log.Printf("Error sending stats photo to chat %s: %v\n", fromChatID, err)
+	}
+}
+
+func sendStatsError(cfg *Config, fromChatID string, err error) {
+	if sendErr := sendTelegramMessage(cfg.TelegramBotToken, fromChatID, err.Error()); sendErr != nil {
+		log.Printf("Error replying to chat %s: %v\n", fromChatID, sendErr)
+	}
+}
+
+// renderStatsChart builds the appropriate chart for label (24h -> line, 7d/30d -> heat-map),
+// reusing a cached render when one is still fresh.
+func renderStatsChart(pool *pgxpool.Pool, venue, label string, window time.Duration) ([]byte, error) {
+	key := chartCacheKey{Venue: venue, Window: label, BucketStart: bucketStart(label, time.Now())}
+
+	return renderCachedChart(key, func() ([]byte, error) {
+		since := time.Now().Add(-window)
+		if label == "24h" {
+			points, err := fetchHourlyBuckets(pool, venue, since)
+			if err != nil {
+				return nil, err
+			}
+			return renderLineChart(venue, points)
+		}
+
+		cells, err := fetchHeatmapBuckets(pool, venue, since)
+		if err != nil {
+			return nil, err
+		}
+		return renderHeatmap(venue, cells)
+	})
+}
+
+func handleSubscribeCommand(pool *pgxpool.Pool, scrapers *ScraperRegistry, cfg *Config, fromChatID string, args []string) string {
+	if !isAuthorized(fromChatID, cfg) {
+		return "You are not allowed to manage subscriptions."
+	}
+	if len(args) != 2 && len(args) != 3 {
+		return "Usage: /subscribe <op> <pct> [venue], e.g. /subscribe <= 30 lazdynai"
+	}
+
+	var venueArg string
+	if len(args) == 3 {
+		venueArg = args[2]
+	}
+	venue, err := resolveVenue(scrapers, venueArg)
+	if err != nil {
+		return err.Error()
+	}
+
+	comparator := args[0]
+	threshold, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Sprintf("Invalid threshold %q", args[1])
+	}
+	switch comparator {
+	case "<=", "<", ">=", ">", "=", "==":
+	default:
+		return fmt.Sprintf("Invalid comparator %q (expected one of <= < >= > =)", comparator)
+	}
+
+	if err := addAlertSubscription(pool, fromChatID, venue, comparator, threshold, defaultAlertCooldown); err != nil {
+		log.Printf("Error adding subscription: %v\n", err)
+		return "Error adding subscription."
+	}
+	return fmt.Sprintf("Subscribed: you'll be notified when %s usage %s %d%%", venue, comparator, threshold)
+}
+
+func handleUnsubscribeCommand(pool *pgxpool.Pool, cfg *Config, fromChatID string, args []string) string {
+	if !isAuthorized(fromChatID, cfg) {
+		return "You are not allowed to manage subscriptions."
+	}
+
+	if len(args) == 0 {
+		subs, err := loadAlertSubscriptionsForChat(pool, fromChatID)
+		if err != nil {
+			log.Printf("Error loading subscriptions: %v\n", err)
+			return "Error loading subscriptions."
+		}
+		if len(subs) == 0 {
+			return "You have no active subscriptions."
+		}
+		var b strings.Builder
+		for _, s := range subs {
+			fmt.Fprintf(&b, "#%d: %s usage %s %d%%\n", s.ID, s.Venue, s.Comparator, s.Threshold)
+		}
+		b.WriteString("\nRemove one with /unsubscribe <id>")
+		return b.String()
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Sprintf("Invalid subscription id %q", args[0])
+	}
+	if err := removeAlertSubscription(pool, fromChatID, id); err != nil {
+		return fmt.Sprintf("No subscription #%d found for you.", id)
+	}
+	return fmt.Sprintf("Unsubscribed from #%d", id)
+}